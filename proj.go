@@ -11,8 +11,10 @@ package projgeom
 import (
 	"io"
 	"io/ioutil"
+	"math"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/lukeroth/gdal"
 	"github.com/pebbe/go-proj-4/proj"
@@ -30,9 +32,9 @@ func (e UnsupportedGeometryError) Error() string {
 // Project geometry from src to dst projection. inputDegrees and outputDegrees are `true` if
 // the input or output geometries is in units of degrees. We need to know this
 // because the Proj4 library works in units of radians.
-// Because I don't know whether to transform Z values from degrees to radians or
-// not, Z values are not supported.
-// I also don't know what to do with M values so they are not supported either.
+// Z values are passed through to proj as a third coordinate, since proj only
+// converts the horizontal components to/from radians. M values are not
+// understood by proj at all, so they are copied through unchanged.
 func project(g geom.T, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
 	if g == nil {
 		return nil, nil
@@ -41,28 +43,31 @@ func project(g geom.T, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (g
 	case geom.Point:
 		point := g.(geom.Point)
 		return projectPoint(&point, src, dst, inputDegrees, outputDegrees)
-	//case geom.PointZ:
-	//	pointZ := g.(geom.PointZ)
-	//	return projectPointZ(&pointZ, src, dst)
-	//case geom.PointM:
-	//	pointM := g.(geom.PointM)
-	//	return projectPointM(&pointM, src, dst)
-	//case geom.PointZM:
-	//	pointZM := g.(geom.PointZM)
-	//	return projectPointZM(&pointZM, src, dst)
+	case geom.PointZ:
+		pointZ := g.(geom.PointZ)
+		return projectPointZ(&pointZ, src, dst, inputDegrees, outputDegrees)
+	case geom.PointM:
+		pointM := g.(geom.PointM)
+		return projectPointM(&pointM, src, dst, inputDegrees, outputDegrees)
+	case geom.PointZM:
+		pointZM := g.(geom.PointZM)
+		return projectPointZM(&pointZM, src, dst, inputDegrees, outputDegrees)
 	case geom.LineString:
 		lineString := g.(geom.LineString)
 		return projectLineString(&lineString, src, dst, inputDegrees,
 			outputDegrees)
-	//case geom.LineStringZ:
-	//	lineStringZ := g.(geom.LineStringZ)
-	//	return projectLineStringZ(&lineStringZ, src, dst)
-	//case geom.LineStringM:
-	//	lineStringM := g.(geom.LineStringM)
-	//	return projectLineStringM(&lineStringM, src, dst)
-	//case geom.LineStringZM:
-	//	lineStringZM := g.(geom.LineStringZM)
-	//	return projectLineStringZM(&lineStringZM, src, dst)
+	case geom.LineStringZ:
+		lineStringZ := g.(geom.LineStringZ)
+		return projectLineStringZ(&lineStringZ, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.LineStringM:
+		lineStringM := g.(geom.LineStringM)
+		return projectLineStringM(&lineStringM, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.LineStringZM:
+		lineStringZM := g.(geom.LineStringZM)
+		return projectLineStringZM(&lineStringZM, src, dst, inputDegrees,
+			outputDegrees)
 	case geom.MultiLineString:
 		multiLineString := g.(geom.MultiLineString)
 		return projectMultiLineString(&multiLineString, src, dst,
@@ -71,68 +76,1120 @@ func project(g geom.T, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (g
 		polygon := g.(geom.Polygon)
 		return projectPolygon(&polygon, src, dst,
 			inputDegrees, outputDegrees)
-	//case geom.PolygonZ:
-	//	polygonZ := g.(geom.PolygonZ)
-	//	return projectPolygonZ(&polygonZ, src, dst)
-	//case geom.PolygonM:
-	//	polygonM := g.(geom.PolygonM)
-	//	return projectPolygonM(&polygonM, src, dst)
-	//case geom.PolygonZM:
-	//	polygonZM := g.(geom.PolygonZM)
-	//	return projectPolygonZM(&polygonZM, src, dst), nil
+	case geom.PolygonZ:
+		polygonZ := g.(geom.PolygonZ)
+		return projectPolygonZ(&polygonZ, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.PolygonM:
+		polygonM := g.(geom.PolygonM)
+		return projectPolygonM(&polygonM, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.PolygonZM:
+		polygonZM := g.(geom.PolygonZM)
+		return projectPolygonZM(&polygonZM, src, dst, inputDegrees,
+			outputDegrees)
 	case geom.MultiPolygon:
 		multiPolygon := g.(geom.MultiPolygon)
 		return projectMultiPolygon(&multiPolygon, src, dst,
 			inputDegrees, outputDegrees)
+	case geom.LinearRing:
+		linearRing := g.(geom.LinearRing)
+		return projectLinearRing(&linearRing, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.MultiPoint:
+		multiPoint := g.(geom.MultiPoint)
+		return projectMultiPoint(&multiPoint, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.MultiPointZ:
+		multiPointZ := g.(geom.MultiPointZ)
+		return projectMultiPointZ(&multiPointZ, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.MultiPointM:
+		multiPointM := g.(geom.MultiPointM)
+		return projectMultiPointM(&multiPointM, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.MultiPointZM:
+		multiPointZM := g.(geom.MultiPointZM)
+		return projectMultiPointZM(&multiPointZM, src, dst, inputDegrees,
+			outputDegrees)
+	case geom.GeometryCollection:
+		gc := g.(geom.GeometryCollection)
+		return projectGeometryCollection(&gc, src, dst, inputDegrees,
+			outputDegrees)
 	default:
 		return nil, &UnsupportedGeometryError{reflect.TypeOf(g)}
 	}
 }
 
+// projTransformMu serializes every call into libproj. Classic proj4 (what
+// github.com/pebbe/go-proj-4 wraps) is not documented as safe for concurrent
+// pj_transform calls even across distinct *proj.Proj handles, and
+// CRSRegistry's caching makes handle-sharing across goroutines the common
+// case rather than the exception, so this can't be scoped to a single
+// handle: it has to cover every transform.
+var projTransformMu sync.Mutex
+
+// projectXY transforms the coordinates in x and y in place through src and
+// dst, converting to and from radians around the proj call as needed. z, if
+// non-nil, is passed through to proj alongside x and y but is never
+// scaled, since proj only treats the horizontal components as angular.
+func projectXY(x, y, z []float64, src, dst *proj.Proj, inputDegrees, outputDegrees bool) error {
+	if inputDegrees {
+		for i := range x {
+			x[i] = proj.DegToRad(x[i])
+			y[i] = proj.DegToRad(y[i])
+		}
+	}
+	projTransformMu.Lock()
+	err := proj.Transform(src, dst, x, y, z)
+	projTransformMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if outputDegrees {
+		for i := range x {
+			x[i] = proj.RadToDeg(x[i])
+			y[i] = proj.RadToDeg(y[i])
+		}
+	}
+	return nil
+}
+
+func projectPoint(p *geom.Point, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y := []float64{p.X}, []float64{p.Y}
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.Point{X: x[0], Y: y[0]}, nil
+}
+
+func projectPointZ(p *geom.PointZ, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, z := []float64{p.X}, []float64{p.Y}, []float64{p.Z}
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.PointZ{X: x[0], Y: y[0], Z: z[0]}, nil
+}
+
+func projectPointM(p *geom.PointM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y := []float64{p.X}, []float64{p.Y}
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.PointM{X: x[0], Y: y[0], M: p.M}, nil
+}
+
+func projectPointZM(p *geom.PointZM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, z := []float64{p.X}, []float64{p.Y}, []float64{p.Z}
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.PointZM{X: x[0], Y: y[0], Z: z[0], M: p.M}, nil
+}
+
+// flattenRings walks rings (the rings of a Polygon, or equally the component
+// LineStrings of a MultiLineString) and collects every X and Y into two
+// contiguous slices, recording the length of each ring so the transformed
+// coordinates can be split back apart in the same shape. This lets a whole
+// geometry cross into libproj with a single call instead of one per point.
+func flattenRings(rings [][]geom.Point) (x, y []float64, lens []int) {
+	lens = make([]int, len(rings))
+	n := 0
+	for i, ring := range rings {
+		lens[i] = len(ring)
+		n += len(ring)
+	}
+	x, y = make([]float64, 0, n), make([]float64, 0, n)
+	for _, ring := range rings {
+		for _, p := range ring {
+			x = append(x, p.X)
+			y = append(y, p.Y)
+		}
+	}
+	return
+}
+
+func unflattenRings(x, y []float64, lens []int) [][]geom.Point {
+	rings := make([][]geom.Point, len(lens))
+	off := 0
+	for i, n := range lens {
+		points := make([]geom.Point, n)
+		for j := 0; j < n; j++ {
+			points[j] = geom.Point{X: x[off+j], Y: y[off+j]}
+		}
+		rings[i] = points
+		off += n
+	}
+	return rings
+}
+
+func flattenRingsZ(rings [][]geom.PointZ) (x, y, z []float64, lens []int) {
+	lens = make([]int, len(rings))
+	n := 0
+	for i, ring := range rings {
+		lens[i] = len(ring)
+		n += len(ring)
+	}
+	x, y, z = make([]float64, 0, n), make([]float64, 0, n), make([]float64, 0, n)
+	for _, ring := range rings {
+		for _, p := range ring {
+			x, y, z = append(x, p.X), append(y, p.Y), append(z, p.Z)
+		}
+	}
+	return
+}
+
+func unflattenRingsZ(x, y, z []float64, lens []int) [][]geom.PointZ {
+	rings := make([][]geom.PointZ, len(lens))
+	off := 0
+	for i, n := range lens {
+		points := make([]geom.PointZ, n)
+		for j := 0; j < n; j++ {
+			points[j] = geom.PointZ{X: x[off+j], Y: y[off+j], Z: z[off+j]}
+		}
+		rings[i] = points
+		off += n
+	}
+	return rings
+}
+
+// swapCoordXY returns a copy of g with every X and Y swapped, leaving Z and
+// M untouched. It is used to move a geometry between the GIS convention of
+// (lon,lat) and a lat-first CRS axis order. Unrecognized types are returned
+// unchanged, matching project()'s own default case.
+func swapCoordXY(g geom.T) geom.T {
+	switch t := g.(type) {
+	case geom.Point:
+		return geom.Point{X: t.Y, Y: t.X}
+	case geom.PointZ:
+		return geom.PointZ{X: t.Y, Y: t.X, Z: t.Z}
+	case geom.PointM:
+		return geom.PointM{X: t.Y, Y: t.X, M: t.M}
+	case geom.PointZM:
+		return geom.PointZM{X: t.Y, Y: t.X, Z: t.Z, M: t.M}
+	case geom.LineString:
+		return geom.LineString{Points: swapPoints(t.Points)}
+	case geom.LineStringZ:
+		return geom.LineStringZ{Points: swapPointZs(t.Points)}
+	case geom.LineStringM:
+		return geom.LineStringM{Points: swapPointMs(t.Points)}
+	case geom.LineStringZM:
+		return geom.LineStringZM{Points: swapPointZMs(t.Points)}
+	case geom.MultiLineString:
+		lineStrings := make([]geom.LineString, len(t.LineStrings))
+		for i, ls := range t.LineStrings {
+			lineStrings[i] = geom.LineString{Points: swapPoints(ls.Points)}
+		}
+		return geom.MultiLineString{LineStrings: lineStrings}
+	case geom.Polygon:
+		return geom.Polygon{Rings: swapRings(t.Rings)}
+	case geom.PolygonZ:
+		rings := make([][]geom.PointZ, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = swapPointZs(ring)
+		}
+		return geom.PolygonZ{Rings: rings}
+	case geom.PolygonM:
+		rings := make([][]geom.PointM, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = swapPointMs(ring)
+		}
+		return geom.PolygonM{Rings: rings}
+	case geom.PolygonZM:
+		rings := make([][]geom.PointZM, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = swapPointZMs(ring)
+		}
+		return geom.PolygonZM{Rings: rings}
+	case geom.MultiPolygon:
+		polygons := make([]geom.Polygon, len(t.Polygons))
+		for i, poly := range t.Polygons {
+			polygons[i] = geom.Polygon{Rings: swapRings(poly.Rings)}
+		}
+		return geom.MultiPolygon{Polygons: polygons}
+	case geom.LinearRing:
+		return geom.LinearRing{Points: swapPoints(t.Points)}
+	case geom.MultiPoint:
+		return geom.MultiPoint{Points: swapPoints(t.Points)}
+	case geom.MultiPointZ:
+		return geom.MultiPointZ{Points: swapPointZs(t.Points)}
+	case geom.MultiPointM:
+		return geom.MultiPointM{Points: swapPointMs(t.Points)}
+	case geom.MultiPointZM:
+		return geom.MultiPointZM{Points: swapPointZMs(t.Points)}
+	case geom.GeometryCollection:
+		geoms := make([]geom.T, len(t.Geoms))
+		for i, child := range t.Geoms {
+			geoms[i] = swapCoordXY(child)
+		}
+		return geom.GeometryCollection{Geoms: geoms}
+	default:
+		return g
+	}
+}
+
+func swapPoints(points []geom.Point) []geom.Point {
+	out := make([]geom.Point, len(points))
+	for i, p := range points {
+		out[i] = geom.Point{X: p.Y, Y: p.X}
+	}
+	return out
+}
+
+func swapPointZs(points []geom.PointZ) []geom.PointZ {
+	out := make([]geom.PointZ, len(points))
+	for i, p := range points {
+		out[i] = geom.PointZ{X: p.Y, Y: p.X, Z: p.Z}
+	}
+	return out
+}
+
+func swapPointMs(points []geom.PointM) []geom.PointM {
+	out := make([]geom.PointM, len(points))
+	for i, p := range points {
+		out[i] = geom.PointM{X: p.Y, Y: p.X, M: p.M}
+	}
+	return out
+}
+
+func swapPointZMs(points []geom.PointZM) []geom.PointZM {
+	out := make([]geom.PointZM, len(points))
+	for i, p := range points {
+		out[i] = geom.PointZM{X: p.Y, Y: p.X, Z: p.Z, M: p.M}
+	}
+	return out
+}
+
+func swapRings(rings [][]geom.Point) [][]geom.Point {
+	out := make([][]geom.Point, len(rings))
+	for i, ring := range rings {
+		out[i] = swapPoints(ring)
+	}
+	return out
+}
+
+func projectLineString(ls *geom.LineString, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, lens := flattenRings([][]geom.Point{ls.Points})
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.LineString{Points: unflattenRings(x, y, lens)[0]}, nil
+}
+
+func projectLineStringZ(ls *geom.LineStringZ, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, z, lens := flattenRingsZ([][]geom.PointZ{ls.Points})
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.LineStringZ{Points: unflattenRingsZ(x, y, z, lens)[0]}, nil
+}
+
+func projectLineStringM(ls *geom.LineStringM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	points := make([]geom.Point, len(ls.Points))
+	m := make([]float64, len(ls.Points))
+	for i, p := range ls.Points {
+		points[i], m[i] = geom.Point{X: p.X, Y: p.Y}, p.M
+	}
+	x, y, lens := flattenRings([][]geom.Point{points})
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)[0]
+	out := make([]geom.PointM, len(projected))
+	for i, p := range projected {
+		out[i] = geom.PointM{X: p.X, Y: p.Y, M: m[i]}
+	}
+	return geom.LineStringM{Points: out}, nil
+}
+
+func projectLineStringZM(ls *geom.LineStringZM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	points := make([]geom.PointZ, len(ls.Points))
+	m := make([]float64, len(ls.Points))
+	for i, p := range ls.Points {
+		points[i], m[i] = geom.PointZ{X: p.X, Y: p.Y, Z: p.Z}, p.M
+	}
+	x, y, z, lens := flattenRingsZ([][]geom.PointZ{points})
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRingsZ(x, y, z, lens)[0]
+	out := make([]geom.PointZM, len(projected))
+	for i, p := range projected {
+		out[i] = geom.PointZM{X: p.X, Y: p.Y, Z: p.Z, M: m[i]}
+	}
+	return geom.LineStringZM{Points: out}, nil
+}
+
+func projectMultiLineString(mls *geom.MultiLineString, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	rings := make([][]geom.Point, len(mls.LineStrings))
+	for i, ls := range mls.LineStrings {
+		rings[i] = ls.Points
+	}
+	x, y, lens := flattenRings(rings)
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)
+	lineStrings := make([]geom.LineString, len(projected))
+	for i, pts := range projected {
+		lineStrings[i] = geom.LineString{Points: pts}
+	}
+	return geom.MultiLineString{LineStrings: lineStrings}, nil
+}
+
+func projectPolygon(poly *geom.Polygon, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, lens := flattenRings(poly.Rings)
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.Polygon{Rings: unflattenRings(x, y, lens)}, nil
+}
+
+func projectPolygonZ(poly *geom.PolygonZ, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, z, lens := flattenRingsZ(poly.Rings)
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.PolygonZ{Rings: unflattenRingsZ(x, y, z, lens)}, nil
+}
+
+func projectPolygonM(poly *geom.PolygonM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	rings := make([][]geom.Point, len(poly.Rings))
+	m := make([][]float64, len(poly.Rings))
+	for i, ring := range poly.Rings {
+		points := make([]geom.Point, len(ring))
+		ringM := make([]float64, len(ring))
+		for j, p := range ring {
+			points[j], ringM[j] = geom.Point{X: p.X, Y: p.Y}, p.M
+		}
+		rings[i], m[i] = points, ringM
+	}
+	x, y, lens := flattenRings(rings)
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)
+	out := make([][]geom.PointM, len(projected))
+	for i, ring := range projected {
+		points := make([]geom.PointM, len(ring))
+		for j, p := range ring {
+			points[j] = geom.PointM{X: p.X, Y: p.Y, M: m[i][j]}
+		}
+		out[i] = points
+	}
+	return geom.PolygonM{Rings: out}, nil
+}
+
+func projectPolygonZM(poly *geom.PolygonZM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	rings := make([][]geom.PointZ, len(poly.Rings))
+	m := make([][]float64, len(poly.Rings))
+	for i, ring := range poly.Rings {
+		points := make([]geom.PointZ, len(ring))
+		ringM := make([]float64, len(ring))
+		for j, p := range ring {
+			points[j], ringM[j] = geom.PointZ{X: p.X, Y: p.Y, Z: p.Z}, p.M
+		}
+		rings[i], m[i] = points, ringM
+	}
+	x, y, z, lens := flattenRingsZ(rings)
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRingsZ(x, y, z, lens)
+	out := make([][]geom.PointZM, len(projected))
+	for i, ring := range projected {
+		points := make([]geom.PointZM, len(ring))
+		for j, p := range ring {
+			points[j] = geom.PointZM{X: p.X, Y: p.Y, Z: p.Z, M: m[i][j]}
+		}
+		out[i] = points
+	}
+	return geom.PolygonZM{Rings: out}, nil
+}
+
+func projectMultiPolygon(mp *geom.MultiPolygon, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	var rings [][]geom.Point
+	polyRingCounts := make([]int, len(mp.Polygons))
+	for i, poly := range mp.Polygons {
+		polyRingCounts[i] = len(poly.Rings)
+		rings = append(rings, poly.Rings...)
+	}
+	x, y, lens := flattenRings(rings)
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)
+	polygons := make([]geom.Polygon, len(mp.Polygons))
+	off := 0
+	for i, n := range polyRingCounts {
+		polygons[i] = geom.Polygon{Rings: projected[off : off+n]}
+		off += n
+	}
+	return geom.MultiPolygon{Polygons: polygons}, nil
+}
+
+func projectLinearRing(lr *geom.LinearRing, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, lens := flattenRings([][]geom.Point{lr.Points})
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.LinearRing{Points: unflattenRings(x, y, lens)[0]}, nil
+}
+
+func projectMultiPoint(mp *geom.MultiPoint, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, lens := flattenRings([][]geom.Point{mp.Points})
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.MultiPoint{Points: unflattenRings(x, y, lens)[0]}, nil
+}
+
+func projectMultiPointZ(mp *geom.MultiPointZ, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	x, y, z, lens := flattenRingsZ([][]geom.PointZ{mp.Points})
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	return geom.MultiPointZ{Points: unflattenRingsZ(x, y, z, lens)[0]}, nil
+}
+
+func projectMultiPointM(mp *geom.MultiPointM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	points := make([]geom.Point, len(mp.Points))
+	m := make([]float64, len(mp.Points))
+	for i, p := range mp.Points {
+		points[i], m[i] = geom.Point{X: p.X, Y: p.Y}, p.M
+	}
+	x, y, lens := flattenRings([][]geom.Point{points})
+	if err := projectXY(x, y, nil, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)[0]
+	out := make([]geom.PointM, len(projected))
+	for i, p := range projected {
+		out[i] = geom.PointM{X: p.X, Y: p.Y, M: m[i]}
+	}
+	return geom.MultiPointM{Points: out}, nil
+}
+
+func projectMultiPointZM(mp *geom.MultiPointZM, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	points := make([]geom.PointZ, len(mp.Points))
+	m := make([]float64, len(mp.Points))
+	for i, p := range mp.Points {
+		points[i], m[i] = geom.PointZ{X: p.X, Y: p.Y, Z: p.Z}, p.M
+	}
+	x, y, z, lens := flattenRingsZ([][]geom.PointZ{points})
+	if err := projectXY(x, y, z, src, dst, inputDegrees, outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRingsZ(x, y, z, lens)[0]
+	out := make([]geom.PointZM, len(projected))
+	for i, p := range projected {
+		out[i] = geom.PointZM{X: p.X, Y: p.Y, Z: p.Z, M: m[i]}
+	}
+	return geom.MultiPointZM{Points: out}, nil
+}
+
+// projectGeometryCollection reprojects each child geometry by dispatching it
+// back through project(), so a GeometryCollection is only as capable as the
+// rest of this file's switch: any type project() can't handle yet fails the
+// same way it would on its own.
+func projectGeometryCollection(gc *geom.GeometryCollection, src, dst *proj.Proj, inputDegrees, outputDegrees bool) (geom.T, error) {
+	geoms := make([]geom.T, len(gc.Geoms))
+	for i, child := range gc.Geoms {
+		child2, err := project(child, src, dst, inputDegrees, outputDegrees)
+		if err != nil {
+			return nil, err
+		}
+		geoms[i] = child2
+	}
+	return geom.GeometryCollection{Geoms: geoms}, nil
+}
+
 type CoordinateTransform struct {
 	src, dst                    *proj.Proj
+	srcDef, dstDef              string
 	sameProj                    bool
 	inputDegrees, outputDegrees bool
+	srcLatFirst, dstLatFirst    bool
+	swapInput, swapOutput       bool
+}
+
+// crsHandle is a reference-counted entry in a CRSRegistry.
+type crsHandle struct {
+	p        *proj.Proj
+	refs     int
+	latFirst bool
+	degrees  bool
+}
+
+// CRSRegistry caches parsed *proj.Proj handles keyed by their canonical
+// proj4/pipeline definition string, so that building many CoordinateTransforms
+// for the same CRS doesn't pay for a fresh cgo allocation every time. It owns
+// the lifecycle of the handles it hands out: each acquire must be matched by
+// a release, and the underlying proj.Proj is only closed once nothing else
+// references it. The registry's own bookkeeping (the map and refcounts) is
+// safe for concurrent use, but acquire routinely hands the same *proj.Proj
+// to more than one CoordinateTransform now, and classic proj4 is not
+// documented as safe for concurrent pj_transform calls against one handle.
+// Actual transforms are additionally serialized through projTransformMu so
+// that sharing a cached handle across goroutines can't race inside libproj.
+type CRSRegistry struct {
+	mu      sync.Mutex
+	handles map[string]*crsHandle
+}
+
+// NewCRSRegistry returns an empty, ready-to-use CRSRegistry.
+func NewCRSRegistry() *CRSRegistry {
+	return &CRSRegistry{handles: make(map[string]*crsHandle)}
+}
+
+// defaultCRSRegistry backs every constructor in this file that doesn't take
+// its own CRSRegistry.
+var defaultCRSRegistry = NewCRSRegistry()
+
+// acquire returns the cached *proj.Proj for def, parsing and caching it if
+// this is the first request for def, along with the degrees/axis-order
+// metadata NewCoordinateTransform would otherwise have to recompute.
+func (r *CRSRegistry) acquire(def string) (p *proj.Proj, latFirst, degrees bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if h, ok := r.handles[def]; ok {
+		h.refs++
+		return h.p, h.latFirst, h.degrees, nil
+	}
+	p, err = proj.NewProj(def)
+	if err != nil {
+		return nil, false, false, err
+	}
+	outDef := pipelineOutputDef(def)
+	h := &crsHandle{
+		p:        p,
+		refs:     1,
+		latFirst: isLatFirstProj4(outDef),
+		degrees:  isDegreesProj4(outDef),
+	}
+	r.handles[def] = h
+	return h.p, h.latFirst, h.degrees, nil
+}
+
+// pipelineOutputDef returns the portion of a proj4 definition whose axis
+// order and units actually describe what comes out the far end. For a
+// plain single-CRS definition that's the whole string, but for a
+// "+proj=pipeline ... +step ... +step ..." definition only the *last*
+// +step applies: earlier steps routinely pass through geographic
+// coordinates mid-pipeline (e.g. a +step +proj=hgridshift bracketed by
+// +step +proj=longlat conversions) that have nothing to do with the
+// pipeline's actual output units/axis order.
+func pipelineOutputDef(def string) string {
+	if !strings.Contains(def, "+proj=pipeline") {
+		return def
+	}
+	steps := strings.Split(def, "+step")
+	return steps[len(steps)-1]
+}
+
+// isDegreesProj4 reports whether a proj4 definition (or, for a pipeline,
+// its final +step - see pipelineOutputDef) describes a geographic CRS
+// whose coordinates are in degrees rather than linear units.
+func isDegreesProj4(proj4 string) bool {
+	return strings.Contains(proj4, "longlat") || strings.Contains(proj4, "latlong")
+}
+
+// release drops one reference to def's handle, closing the underlying
+// proj.Proj once nothing else holds it.
+func (r *CRSRegistry) release(def string) {
+	if def == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.handles[def]
+	if !ok {
+		return
+	}
+	h.refs--
+	if h.refs <= 0 {
+		h.p.Close()
+		delete(r.handles, def)
+	}
+}
+
+// isLatFirstProj4 reports whether a proj4 definition (or, for a pipeline,
+// its final +step - see pipelineOutputDef) declares a lat-first axis order
+// via +axis=. Proj >= 6 does this for many EPSG CRSes (e.g. EPSG:4326),
+// which otherwise silently produces swapped output through project() since
+// GIS code conventionally assumes (lon,lat)/(easting,northing).
+func isLatFirstProj4(proj4 string) bool {
+	for _, tok := range strings.Fields(proj4) {
+		axis := strings.TrimPrefix(tok, "+axis=")
+		if axis == tok || axis == "" {
+			continue
+		}
+		return axis[0] == 'n' || axis[0] == 's'
+	}
+	return false
 }
 
 func NewCoordinateTransform(src, dst gdal.SpatialReference) (
 	ct *CoordinateTransform, err error) {
 	ct = new(CoordinateTransform)
 	ct.sameProj = src.IsSame(dst)
-	var srcproj, dstproj string
 	if !ct.sameProj {
-		srcproj, err = src.ToProj4()
+		ct.srcDef, err = src.ToProj4()
 		if err != nil && err.Error() != "No Error" {
 			return
 		}
-		ct.inputDegrees = strings.Contains(srcproj, "longlat") ||
-			strings.Contains(srcproj, "latlong")
-		ct.src, err = proj.NewProj(srcproj)
+		ct.src, ct.srcLatFirst, ct.inputDegrees, err = defaultCRSRegistry.acquire(ct.srcDef)
 		if err != nil {
 			return
 		}
 
-		dstproj, err = dst.ToProj4()
+		ct.dstDef, err = dst.ToProj4()
 		if err != nil && err.Error() != "No Error" {
+			defaultCRSRegistry.release(ct.srcDef)
 			return
 		}
-		ct.outputDegrees = strings.Contains(dstproj, "longlat") ||
-			strings.Contains(dstproj, "latlong")
-		ct.dst, err = proj.NewProj(dstproj)
+		ct.dst, ct.dstLatFirst, ct.outputDegrees, err = defaultCRSRegistry.acquire(ct.dstDef)
 		if err != nil {
+			defaultCRSRegistry.release(ct.srcDef)
 			return
 		}
 	}
 	return
 }
 
+// NewCoordinateTransformFromEPSG builds a CoordinateTransform directly from
+// a pair of EPSG codes. It goes through gdal to expand each code to its
+// full proj4 definition (the same path NewCoordinateTransform uses) rather
+// than caching the bare "+init=epsg:N" form, because acquire's degrees/
+// lat-first detection string-sniffs the definition it's handed: the short
+// init form never contains "longlat" or "+axis=" even when the CRS it
+// resolves to is geographic and lat-first, which would otherwise make
+// Reproject silently skip the deg<->rad conversion.
+func NewCoordinateTransformFromEPSG(srcEPSG, dstEPSG int) (*CoordinateTransform, error) {
+	src := gdal.CreateSpatialReference("")
+	if err := src.FromEPSG(srcEPSG); err != nil {
+		return nil, err
+	}
+	dst := gdal.CreateSpatialReference("")
+	if err := dst.FromEPSG(dstEPSG); err != nil {
+		return nil, err
+	}
+	return NewCoordinateTransform(src, dst)
+}
+
+// NewCoordinateTransformFromProj4 builds a CoordinateTransform from a pair
+// of proj4 definition strings, bypassing gdal.SpatialReference entirely.
+func NewCoordinateTransformFromProj4(src, dst string) (ct *CoordinateTransform, err error) {
+	ct = &CoordinateTransform{srcDef: src, dstDef: dst, sameProj: src == dst}
+	if ct.sameProj {
+		return ct, nil
+	}
+	ct.src, ct.srcLatFirst, ct.inputDegrees, err = defaultCRSRegistry.acquire(src)
+	if err != nil {
+		return nil, err
+	}
+	ct.dst, ct.dstLatFirst, ct.outputDegrees, err = defaultCRSRegistry.acquire(dst)
+	if err != nil {
+		defaultCRSRegistry.release(src)
+		return nil, err
+	}
+	return ct, nil
+}
+
+// NewCoordinateTransformFromPipeline builds a CoordinateTransform from a
+// single proj pipeline/operator string, e.g.
+// "+proj=pipeline +step +proj=utm +zone=33 +step ...", so that a multi-step
+// transform (datum shift + projection + unit conversion, chained) applies
+// as one Reproject call. As PROJ itself recommends, the pipeline is fed
+// coordinates as though the source were a plain geographic CRS in degrees;
+// its own +step operations perform everything else, including any inverse
+// projection the pipeline needs.
+func NewCoordinateTransformFromPipeline(pipeline string) (ct *CoordinateTransform, err error) {
+	ct = &CoordinateTransform{dstDef: pipeline}
+	const geographic = "+proj=longlat +datum=WGS84"
+	ct.srcDef = geographic
+	ct.src, ct.srcLatFirst, ct.inputDegrees, err = defaultCRSRegistry.acquire(geographic)
+	if err != nil {
+		return nil, err
+	}
+	ct.dst, ct.dstLatFirst, ct.outputDegrees, err = defaultCRSRegistry.acquire(pipeline)
+	if err != nil {
+		defaultCRSRegistry.release(geographic)
+		return nil, err
+	}
+	return ct, nil
+}
+
+// Close releases ct's underlying proj handles back to the CRS cache,
+// closing each one once nothing else references its definition. ct must
+// not be used after Close.
+func (ct *CoordinateTransform) Close() {
+	if ct.sameProj {
+		return
+	}
+	defaultCRSRegistry.release(ct.srcDef)
+	defaultCRSRegistry.release(ct.dstDef)
+}
+
+// NormalizeForVisualization configures ct so that Reproject and
+// ReprojectBatch always accept and return geometries in the GIS convention
+// of (longitude/easting, latitude/northing), regardless of whether the
+// src or dst CRS declares a lat-first axis order. This mirrors the switch
+// PostGIS made away from its own bespoke axis-swapping code once proj
+// started reporting +axis in CRS definitions: detect the declared order
+// once, up front, rather than special-casing individual EPSG codes.
+func (ct *CoordinateTransform) NormalizeForVisualization() {
+	ct.swapInput = ct.srcLatFirst
+	ct.swapOutput = ct.dstLatFirst
+}
+
 func (ct *CoordinateTransform) Reproject(g geom.T) (geom.T, error) {
 	if ct.sameProj {
 		return g, nil
 	}
+	if ct.swapInput {
+		g = swapCoordXY(g)
+	}
 	g2, err := project(g, ct.src, ct.dst,
 		ct.inputDegrees, ct.outputDegrees)
-	return g2, err
+	if err != nil {
+		return nil, err
+	}
+	if ct.swapOutput {
+		g2 = swapCoordXY(g2)
+	}
+	return g2, nil
+}
+
+// ReprojectDensified reprojects g after first inserting extra vertices
+// along every LineString/Polygon edge so that no source-space edge is
+// longer than maxSegmentLength (in source units). A straight edge in one
+// CRS does not generally stay straight, or stay on the geodesic, once
+// reprojected into another, which without densifying first produces a
+// visibly "too straight" polyline/polygon boundary. Interpolation is
+// linear in source coordinates for projected sources, and along the
+// great-circle (treating the source CRS as a sphere) for geographic
+// sources. Z and M, where present, are linearly interpolated across
+// inserted vertices by the same fraction as the horizontal interpolation.
+// Points and MultiPoint have no edges and pass through as given.
+// LinearRing and GeometryCollection are also passed through without
+// densifying, so the members of a collection keep only their original
+// vertices.
+func (ct *CoordinateTransform) ReprojectDensified(g geom.T, maxSegmentLength float64) (geom.T, error) {
+	return ct.Reproject(densify(g, maxSegmentLength, ct.inputDegrees))
+}
+
+func densify(g geom.T, maxSegmentLength float64, geographic bool) geom.T {
+	switch t := g.(type) {
+	case geom.LineString:
+		return geom.LineString{Points: densifyPoints(t.Points, maxSegmentLength, geographic)}
+	case geom.MultiLineString:
+		lineStrings := make([]geom.LineString, len(t.LineStrings))
+		for i, ls := range t.LineStrings {
+			lineStrings[i] = geom.LineString{Points: densifyPoints(ls.Points, maxSegmentLength, geographic)}
+		}
+		return geom.MultiLineString{LineStrings: lineStrings}
+	case geom.Polygon:
+		rings := make([][]geom.Point, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = densifyPoints(ring, maxSegmentLength, geographic)
+		}
+		return geom.Polygon{Rings: rings}
+	case geom.MultiPolygon:
+		polygons := make([]geom.Polygon, len(t.Polygons))
+		for i, poly := range t.Polygons {
+			rings := make([][]geom.Point, len(poly.Rings))
+			for j, ring := range poly.Rings {
+				rings[j] = densifyPoints(ring, maxSegmentLength, geographic)
+			}
+			polygons[i] = geom.Polygon{Rings: rings}
+		}
+		return geom.MultiPolygon{Polygons: polygons}
+	case geom.LineStringZ:
+		return geom.LineStringZ{Points: densifyPointsZ(t.Points, maxSegmentLength, geographic)}
+	case geom.LineStringM:
+		return geom.LineStringM{Points: densifyPointsM(t.Points, maxSegmentLength, geographic)}
+	case geom.LineStringZM:
+		return geom.LineStringZM{Points: densifyPointsZM(t.Points, maxSegmentLength, geographic)}
+	case geom.PolygonZ:
+		rings := make([][]geom.PointZ, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = densifyPointsZ(ring, maxSegmentLength, geographic)
+		}
+		return geom.PolygonZ{Rings: rings}
+	case geom.PolygonM:
+		rings := make([][]geom.PointM, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = densifyPointsM(ring, maxSegmentLength, geographic)
+		}
+		return geom.PolygonM{Rings: rings}
+	case geom.PolygonZM:
+		rings := make([][]geom.PointZM, len(t.Rings))
+		for i, ring := range t.Rings {
+			rings[i] = densifyPointsZM(ring, maxSegmentLength, geographic)
+		}
+		return geom.PolygonZM{Rings: rings}
+	default:
+		// Points and MultiPoint have no edges to densify; LinearRing
+		// and GeometryCollection aren't handled here and are passed
+		// through as-is.
+		return g
+	}
+}
+
+func densifyPoints(points []geom.Point, maxSegmentLength float64, geographic bool) []geom.Point {
+	if len(points) < 2 || maxSegmentLength <= 0 {
+		return points
+	}
+	out := make([]geom.Point, 0, len(points))
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		out = append(out, a)
+		extra, _ := interpolateSegment(a, b, maxSegmentLength, geographic)
+		out = append(out, extra...)
+	}
+	return append(out, points[len(points)-1])
+}
+
+// densifyPointsZ densifies the X,Y of each edge the same way densifyPoints
+// does. Z is not itself reprojected along a geodesic - it's linearly
+// interpolated across each inserted vertex by the same fraction used for
+// the horizontal interpolation, so elevation carries through smoothly
+// rather than being dropped or left at a stale value.
+func densifyPointsZ(points []geom.PointZ, maxSegmentLength float64, geographic bool) []geom.PointZ {
+	if len(points) < 2 || maxSegmentLength <= 0 {
+		return points
+	}
+	out := make([]geom.PointZ, 0, len(points))
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		out = append(out, a)
+		xy, fracs := interpolateSegment(geom.Point{X: a.X, Y: a.Y}, geom.Point{X: b.X, Y: b.Y}, maxSegmentLength, geographic)
+		for k, p := range xy {
+			out = append(out, geom.PointZ{X: p.X, Y: p.Y, Z: a.Z + (b.Z-a.Z)*fracs[k]})
+		}
+	}
+	return append(out, points[len(points)-1])
+}
+
+// densifyPointsM densifies X,Y the same way densifyPoints does, linearly
+// interpolating M across each inserted vertex (see densifyPointsZ).
+func densifyPointsM(points []geom.PointM, maxSegmentLength float64, geographic bool) []geom.PointM {
+	if len(points) < 2 || maxSegmentLength <= 0 {
+		return points
+	}
+	out := make([]geom.PointM, 0, len(points))
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		out = append(out, a)
+		xy, fracs := interpolateSegment(geom.Point{X: a.X, Y: a.Y}, geom.Point{X: b.X, Y: b.Y}, maxSegmentLength, geographic)
+		for k, p := range xy {
+			out = append(out, geom.PointM{X: p.X, Y: p.Y, M: a.M + (b.M-a.M)*fracs[k]})
+		}
+	}
+	return append(out, points[len(points)-1])
+}
+
+// densifyPointsZM densifies X,Y the same way densifyPoints does, linearly
+// interpolating both Z and M across each inserted vertex (see
+// densifyPointsZ).
+func densifyPointsZM(points []geom.PointZM, maxSegmentLength float64, geographic bool) []geom.PointZM {
+	if len(points) < 2 || maxSegmentLength <= 0 {
+		return points
+	}
+	out := make([]geom.PointZM, 0, len(points))
+	for i := 0; i < len(points)-1; i++ {
+		a, b := points[i], points[i+1]
+		out = append(out, a)
+		xy, fracs := interpolateSegment(geom.Point{X: a.X, Y: a.Y}, geom.Point{X: b.X, Y: b.Y}, maxSegmentLength, geographic)
+		for k, p := range xy {
+			out = append(out, geom.PointZM{
+				X: p.X, Y: p.Y,
+				Z: a.Z + (b.Z-a.Z)*fracs[k],
+				M: a.M + (b.M-a.M)*fracs[k],
+			})
+		}
+	}
+	return append(out, points[len(points)-1])
+}
+
+// interpolateSegment returns the vertices (excluding a and b themselves)
+// needed to split a->b into sub-segments no longer than maxSegmentLength,
+// along with the fraction of the way from a to b each one represents, so
+// callers carrying extra channels (Z, M) alongside X,Y can interpolate
+// those in step.
+func interpolateSegment(a, b geom.Point, maxSegmentLength float64, geographic bool) ([]geom.Point, []float64) {
+	var dist float64
+	if geographic {
+		dist = haversineDegrees(a, b)
+	} else {
+		dist = math.Hypot(b.X-a.X, b.Y-a.Y)
+	}
+	if dist <= maxSegmentLength || dist == 0 {
+		return nil, nil
+	}
+	n := int(math.Ceil(dist / maxSegmentLength))
+	points := make([]geom.Point, 0, n-1)
+	fracs := make([]float64, 0, n-1)
+	for i := 1; i < n; i++ {
+		f := float64(i) / float64(n)
+		if geographic {
+			points = append(points, slerp(a, b, f))
+		} else {
+			points = append(points, geom.Point{X: a.X + (b.X-a.X)*f, Y: a.Y + (b.Y-a.Y)*f})
+		}
+		fracs = append(fracs, f)
+	}
+	return points, fracs
+}
+
+// haversineDegrees returns the great-circle central angle between a and b,
+// in degrees, treating both as (lon,lat) degrees on a sphere.
+//
+// This is a deliberate simplification: it does not take the source CRS's
+// actual ellipsoid (semi-major axis, flattening) from proj.Proj into
+// account, so segment lengths and the midpoints slerp below produces are
+// off by the ellipsoid's flattening (under ~0.34% for WGS84) rather than
+// being exact inverse-geodesic distances/positions. That's within noise
+// for choosing how finely to chop a segment for rendering, but callers
+// doing anything precision-sensitive with the inserted vertices should
+// know the curve they're walking is spherical, not the source ellipsoid.
+func haversineDegrees(a, b geom.Point) float64 {
+	lat1, lon1 := a.Y*math.Pi/180, a.X*math.Pi/180
+	lat2, lon2 := b.Y*math.Pi/180, b.X*math.Pi/180
+	dLat, dLon := lat2-lat1, lon2-lon1
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	c := 2 * math.Atan2(math.Sqrt(h), math.Sqrt(1-h))
+	return c * 180 / math.Pi
+}
+
+// slerp returns the point a fraction f of the way from a to b along the
+// great circle connecting them, both given as (lon,lat) degrees.
+func slerp(a, b geom.Point, f float64) geom.Point {
+	lat1, lon1 := a.Y*math.Pi/180, a.X*math.Pi/180
+	lat2, lon2 := b.Y*math.Pi/180, b.X*math.Pi/180
+	x1, y1, z1 := math.Cos(lat1)*math.Cos(lon1), math.Cos(lat1)*math.Sin(lon1), math.Sin(lat1)
+	x2, y2, z2 := math.Cos(lat2)*math.Cos(lon2), math.Cos(lat2)*math.Sin(lon2), math.Sin(lat2)
+	dot := x1*x2 + y1*y2 + z1*z2
+	if dot > 1 {
+		dot = 1
+	} else if dot < -1 {
+		dot = -1
+	}
+	d := math.Acos(dot)
+	if d == 0 {
+		return a
+	}
+	A, B := math.Sin((1-f)*d)/math.Sin(d), math.Sin(f*d)/math.Sin(d)
+	x, y, z := A*x1+B*x2, A*y1+B*y2, A*z1+B*z2
+	lat := math.Atan2(z, math.Sqrt(x*x+y*y))
+	lon := math.Atan2(y, x)
+	return geom.Point{X: lon * 180 / math.Pi, Y: lat * 180 / math.Pi}
+}
+
+// flattenGeom breaks g down into its constituent rings of plain X,Y points
+// and returns a function that can reassemble the projected rings back into
+// a geometry of g's concrete type. It only handles the 2D types project can
+// batch-flatten (Point, LineString, MultiLineString, Polygon, MultiPolygon);
+// ok is false for everything else, including the Z/M variants, since those
+// carry channels flattenRings/unflattenRings don't know about.
+func flattenGeom(g geom.T) (rings [][]geom.Point, rebuild func([][]geom.Point) geom.T, ok bool) {
+	switch t := g.(type) {
+	case geom.Point:
+		return [][]geom.Point{{t}}, func(r [][]geom.Point) geom.T {
+			return r[0][0]
+		}, true
+	case geom.LineString:
+		return [][]geom.Point{t.Points}, func(r [][]geom.Point) geom.T {
+			return geom.LineString{Points: r[0]}
+		}, true
+	case geom.MultiLineString:
+		rings := make([][]geom.Point, len(t.LineStrings))
+		for i, ls := range t.LineStrings {
+			rings[i] = ls.Points
+		}
+		return rings, func(r [][]geom.Point) geom.T {
+			lineStrings := make([]geom.LineString, len(r))
+			for i, pts := range r {
+				lineStrings[i] = geom.LineString{Points: pts}
+			}
+			return geom.MultiLineString{LineStrings: lineStrings}
+		}, true
+	case geom.Polygon:
+		return t.Rings, func(r [][]geom.Point) geom.T {
+			return geom.Polygon{Rings: r}
+		}, true
+	case geom.MultiPolygon:
+		var rings [][]geom.Point
+		ringCounts := make([]int, len(t.Polygons))
+		for i, poly := range t.Polygons {
+			ringCounts[i] = len(poly.Rings)
+			rings = append(rings, poly.Rings...)
+		}
+		return rings, func(r [][]geom.Point) geom.T {
+			polygons := make([]geom.Polygon, len(ringCounts))
+			off := 0
+			for i, n := range ringCounts {
+				polygons[i] = geom.Polygon{Rings: r[off : off+n]}
+				off += n
+			}
+			return geom.MultiPolygon{Polygons: polygons}
+		}, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// ReprojectBatch reprojects many geometries with a single underlying proj
+// call, which for a feature collection with thousands of vertices avoids
+// the per-geometry cgo crossing into libproj that calling Reproject in a
+// loop would incur. Geometries flattenGeom does not recognize (the Z/M
+// variants, MultiPoint, GeometryCollection, ...) are still reprojected
+// correctly, just individually, via Reproject.
+func (ct *CoordinateTransform) ReprojectBatch(gs []geom.T) ([]geom.T, error) {
+	if ct.sameProj {
+		return gs, nil
+	}
+	out := make([]geom.T, len(gs))
+	batchIndices := make([]int, 0, len(gs))
+	rebuilders := make([]func([][]geom.Point) geom.T, 0, len(gs))
+	ringCounts := make([]int, 0, len(gs))
+	var allRings [][]geom.Point
+	for i, g := range gs {
+		if ct.swapInput {
+			g = swapCoordXY(g)
+		}
+		rings, rebuild, ok := flattenGeom(g)
+		if !ok {
+			g2, err := ct.Reproject(gs[i])
+			if err != nil {
+				return nil, err
+			}
+			out[i] = g2
+			continue
+		}
+		batchIndices = append(batchIndices, i)
+		rebuilders = append(rebuilders, rebuild)
+		ringCounts = append(ringCounts, len(rings))
+		allRings = append(allRings, rings...)
+	}
+	x, y, lens := flattenRings(allRings)
+	if err := projectXY(x, y, nil, ct.src, ct.dst, ct.inputDegrees, ct.outputDegrees); err != nil {
+		return nil, err
+	}
+	projected := unflattenRings(x, y, lens)
+	off := 0
+	for j, i := range batchIndices {
+		n := ringCounts[j]
+		g2 := rebuilders[j](projected[off : off+n])
+		if ct.swapOutput {
+			g2 = swapCoordXY(g2)
+		}
+		out[i] = g2
+		off += n
+	}
+	return out, nil
 }
 
 // ReadPrj reads an ESRI '.prj' projection file and
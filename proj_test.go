@@ -0,0 +1,249 @@
+package projgeom
+
+import (
+	"math"
+	"testing"
+
+	"github.com/twpayne/gogeom/geom"
+)
+
+// TestPipelineOutputDef checks that a plain proj4 definition passes through
+// unchanged, while a pipeline definition is trimmed down to its final
+// +step - the only part that describes what the pipeline actually outputs.
+func TestPipelineOutputDef(t *testing.T) {
+	cases := []struct {
+		name string
+		def  string
+		want string
+	}{
+		{
+			name: "plain proj4 definition is unaffected",
+			def:  "+proj=longlat +datum=WGS84",
+			want: "+proj=longlat +datum=WGS84",
+		},
+		{
+			name: "pipeline collapses to its last step",
+			def:  "+proj=pipeline +step +proj=longlat +step +proj=hgridshift +grids=foo +step +proj=utm +zone=33",
+			want: " +proj=utm +zone=33",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := pipelineOutputDef(c.def); got != c.want {
+				t.Errorf("pipelineOutputDef(%q) = %q, want %q", c.def, got, c.want)
+			}
+		})
+	}
+}
+
+// TestDegreesAndLatFirstIgnoreIntermediatePipelineSteps is a regression
+// test for a pipeline whose intermediate steps pass through geographic
+// coordinates (e.g. a horizontal grid shift bracketed by +proj=longlat
+// conversions) even though its final output is projected, and the reverse
+// case where the pipeline's last step actually is geographic. Both degrees
+// and lat-first detection must key off the last +step, not the whole
+// pipeline string.
+func TestDegreesAndLatFirstIgnoreIntermediatePipelineSteps(t *testing.T) {
+	cases := []struct {
+		name         string
+		def          string
+		wantDegrees  bool
+		wantLatFirst bool
+	}{
+		{
+			name:         "plain geographic proj4",
+			def:          "+proj=longlat +datum=WGS84",
+			wantDegrees:  true,
+			wantLatFirst: false,
+		},
+		{
+			name:         "plain projected proj4",
+			def:          "+proj=utm +zone=15 +datum=WGS84",
+			wantDegrees:  false,
+			wantLatFirst: false,
+		},
+		{
+			name:         "plain geographic proj4 with lat-first axis",
+			def:          "+proj=longlat +datum=WGS84 +axis=neu",
+			wantDegrees:  true,
+			wantLatFirst: true,
+		},
+		{
+			name: "pipeline passing through longlat mid-pipeline " +
+				"but ending in projected meters",
+			def:          "+proj=pipeline +step +proj=longlat +step +proj=hgridshift +grids=foo +step +proj=utm +zone=33",
+			wantDegrees:  false,
+			wantLatFirst: false,
+		},
+		{
+			name:         "pipeline ending in a geographic, lat-first step",
+			def:          "+proj=pipeline +step +proj=utm +inv +zone=33 +step +proj=longlat +axis=neu",
+			wantDegrees:  true,
+			wantLatFirst: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			outDef := pipelineOutputDef(c.def)
+			if got := isDegreesProj4(outDef); got != c.wantDegrees {
+				t.Errorf("isDegreesProj4(pipelineOutputDef(%q)) = %v, want %v", c.def, got, c.wantDegrees)
+			}
+			if got := isLatFirstProj4(outDef); got != c.wantLatFirst {
+				t.Errorf("isLatFirstProj4(pipelineOutputDef(%q)) = %v, want %v", c.def, got, c.wantLatFirst)
+			}
+		})
+	}
+}
+
+// TestFlattenUnflattenRings exercises the ring-length bookkeeping that
+// projectPolygon, projectMultiLineString, and ReprojectBatch all depend on
+// to reassemble a batched proj call back into the original geometry shape.
+func TestFlattenUnflattenRings(t *testing.T) {
+	rings := [][]geom.Point{
+		{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 2}},
+		{{X: 10, Y: 10}, {X: 11, Y: 11}},
+		{},
+	}
+	x, y, lens := flattenRings(rings)
+	if len(x) != 5 || len(y) != 5 {
+		t.Fatalf("expected 5 flattened coordinates, got %d", len(x))
+	}
+	got := unflattenRings(x, y, lens)
+	if len(got) != len(rings) {
+		t.Fatalf("expected %d rings back, got %d", len(rings), len(got))
+	}
+	for i, ring := range rings {
+		if len(got[i]) != len(ring) {
+			t.Fatalf("ring %d: expected %d points, got %d", i, len(ring), len(got[i]))
+		}
+		for j, p := range ring {
+			if got[i][j] != p {
+				t.Errorf("ring %d point %d: expected %v, got %v", i, j, p, got[i][j])
+			}
+		}
+	}
+}
+
+// TestFlattenGeomMultiPolygonRoundTrip exercises the per-polygon ring offset
+// arithmetic in flattenGeom's rebuild function, which ReprojectBatch relies
+// on to split a batched proj call back into a MultiPolygon's polygons. The
+// per-geometry split across several geometries is not covered here.
+func TestFlattenGeomMultiPolygonRoundTrip(t *testing.T) {
+	mp := geom.MultiPolygon{Polygons: []geom.Polygon{
+		{Rings: [][]geom.Point{
+			{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}},
+		}},
+		{Rings: [][]geom.Point{
+			{{X: 5, Y: 5}, {X: 6, Y: 5}, {X: 6, Y: 6}, {X: 5, Y: 5}},
+			{{X: 5.1, Y: 5.1}, {X: 5.2, Y: 5.1}, {X: 5.1, Y: 5.1}},
+		}},
+	}}
+	rings, rebuild, ok := flattenGeom(mp)
+	if !ok {
+		t.Fatal("flattenGeom should recognize geom.MultiPolygon")
+	}
+	x, y, lens := flattenRings(rings)
+	got := rebuild(unflattenRings(x, y, lens))
+	gotMP, ok := got.(geom.MultiPolygon)
+	if !ok {
+		t.Fatalf("expected geom.MultiPolygon, got %T", got)
+	}
+	if len(gotMP.Polygons) != len(mp.Polygons) {
+		t.Fatalf("expected %d polygons, got %d", len(mp.Polygons), len(gotMP.Polygons))
+	}
+	for i, poly := range mp.Polygons {
+		if len(gotMP.Polygons[i].Rings) != len(poly.Rings) {
+			t.Fatalf("polygon %d: expected %d rings, got %d", i, len(poly.Rings), len(gotMP.Polygons[i].Rings))
+		}
+		for j, ring := range poly.Rings {
+			gotRing := gotMP.Polygons[i].Rings[j]
+			if len(gotRing) != len(ring) {
+				t.Fatalf("polygon %d ring %d: expected %d points, got %d", i, j, len(ring), len(gotRing))
+			}
+			for k, p := range ring {
+				if gotRing[k] != p {
+					t.Errorf("polygon %d ring %d point %d: expected %v, got %v", i, j, k, p, gotRing[k])
+				}
+			}
+		}
+	}
+}
+
+// TestSwapCoordXYRoundTrip checks that axis normalization swaps X/Y and
+// that swapping twice returns the original geometry, across the nested
+// Polygon nesting swapCoordXY has to walk.
+func TestSwapCoordXYRoundTrip(t *testing.T) {
+	g := geom.Polygon{Rings: [][]geom.Point{{{X: 1, Y: 2}, {X: 3, Y: 4}}}}
+	swapped, ok := swapCoordXY(g).(geom.Polygon)
+	if !ok {
+		t.Fatalf("expected geom.Polygon, got %T", swapCoordXY(g))
+	}
+	if swapped.Rings[0][0].X != 2 || swapped.Rings[0][0].Y != 1 {
+		t.Fatalf("expected swapped point (2,1), got (%v,%v)", swapped.Rings[0][0].X, swapped.Rings[0][0].Y)
+	}
+	back, ok := swapCoordXY(swapped).(geom.Polygon)
+	if !ok {
+		t.Fatalf("expected geom.Polygon, got %T", swapCoordXY(swapped))
+	}
+	if back.Rings[0][0] != g.Rings[0][0] {
+		t.Fatalf("swapping twice should be the identity, got %v, want %v", back.Rings[0][0], g.Rings[0][0])
+	}
+}
+
+// TestDensifyPointsPlanar checks that densifying a straight planar segment
+// produces enough vertices to keep every sub-segment under the requested
+// length, without disturbing the endpoints.
+func TestDensifyPointsPlanar(t *testing.T) {
+	points := []geom.Point{{X: 0, Y: 0}, {X: 10, Y: 0}}
+	out := densifyPoints(points, 3, false)
+	if len(out) != 5 {
+		t.Fatalf("expected 5 points densifying a length-10 segment at maxSegmentLength=3, got %d", len(out))
+	}
+	if out[0] != points[0] || out[len(out)-1] != points[len(points)-1] {
+		t.Fatalf("densify should not move the original endpoints, got %v", out)
+	}
+	for i := 0; i < len(out)-1; i++ {
+		d := math.Hypot(out[i+1].X-out[i].X, out[i+1].Y-out[i].Y)
+		if d > 3+1e-9 {
+			t.Errorf("segment %d length %v exceeds maxSegmentLength of 3", i, d)
+		}
+	}
+}
+
+// TestDensifyPointsZInterpolatesElevation checks that densifying a 3D
+// segment carries Z along by linear interpolation rather than dropping it
+// or leaving inserted vertices at a stale value.
+func TestDensifyPointsZInterpolatesElevation(t *testing.T) {
+	points := []geom.PointZ{{X: 0, Y: 0, Z: 0}, {X: 10, Y: 0, Z: 100}}
+	out := densifyPointsZ(points, 3, false)
+	if len(out) != 5 {
+		t.Fatalf("expected 5 points, got %d", len(out))
+	}
+	for i, p := range out {
+		wantZ := p.X / 10 * 100
+		if math.Abs(p.Z-wantZ) > 1e-9 {
+			t.Errorf("point %d: Z = %v, want %v (linear in X)", i, p.Z, wantZ)
+		}
+	}
+}
+
+func TestHaversineDegreesZero(t *testing.T) {
+	p := geom.Point{X: -93.0, Y: 45.0}
+	if d := haversineDegrees(p, p); d != 0 {
+		t.Errorf("expected 0 distance between a point and itself, got %v", d)
+	}
+}
+
+// TestSlerpEndpoints checks that the great-circle interpolation used by
+// geographic densification reproduces its endpoints at f=0 and f=1, to
+// within floating-point tolerance.
+func TestSlerpEndpoints(t *testing.T) {
+	a := geom.Point{X: -93.0, Y: 45.0}
+	b := geom.Point{X: -90.0, Y: 46.0}
+	if got := slerp(a, b, 0); math.Abs(got.X-a.X) > 1e-9 || math.Abs(got.Y-a.Y) > 1e-9 {
+		t.Errorf("slerp(a, b, 0) = %v, want %v", got, a)
+	}
+	if got := slerp(a, b, 1); math.Abs(got.X-b.X) > 1e-6 || math.Abs(got.Y-b.Y) > 1e-6 {
+		t.Errorf("slerp(a, b, 1) = %v, want %v", got, b)
+	}
+}